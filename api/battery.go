@@ -0,0 +1,23 @@
+package api
+
+import "context"
+
+// BatteryModule is the per-module telemetry returned by the
+// /api/v2/battery endpoint, useful for spotting a single failing module
+// before it takes down the whole battery pack.
+type BatteryModule struct {
+	Name               string  `json:"name"`
+	TemperatureCelsius float64 `json:"temperature_celsius"`
+	Voltage            float64 `json:"voltage"`
+	CycleCount         int     `json:"cycle_count"`
+}
+
+// GetBatteryModules fetches the per-module telemetry of the battery pack.
+// It requires an API token.
+func (s *v2Source) GetBatteryModules(ctx context.Context) ([]BatteryModule, error) {
+	var modules []BatteryModule
+	if err := s.get(ctx, "/api/v2/battery", &modules); err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
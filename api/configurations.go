@@ -0,0 +1,24 @@
+package api
+
+import "context"
+
+// Configurations is the response of the /api/v2/configurations endpoint. It
+// describes the identity of the device rather than its current operating
+// state, and changes rarely compared to Status or LatestData.
+type Configurations struct {
+	Serial        string `json:"serial"`
+	Model         string `json:"model"`
+	BatterySystem string `json:"battery_system"`
+	Firmware      string `json:"firmware_version"`
+	OperatingMode string `json:"operating_mode"`
+}
+
+// GetConfigurations fetches the device's identity information. It requires
+// an API token.
+func (s *v2Source) GetConfigurations(ctx context.Context) (*Configurations, error) {
+	var cfg Configurations
+	if err := s.get(ctx, "/api/v2/configurations", &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
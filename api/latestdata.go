@@ -0,0 +1,29 @@
+package api
+
+import "context"
+
+// IcStatus holds the inverter controller status fields relevant to charge
+// cycle tracking.
+type IcStatus struct {
+	SecondsSinceFullCharge int64 `json:"secondssincefullcharge"`
+}
+
+// LatestData is the response of the /api/v2/latestdata endpoint.
+type LatestData struct {
+	IcStatus           IcStatus `json:"ic_status"`
+	FullChargeCapacity int      `json:"FullChargeCapacity"`
+	InverterPowerW     float64  `json:"Inverter_Power_W"`
+	// GridFeedInW is positive while feeding power into the grid and
+	// negative while purchasing power from it.
+	GridFeedInW float64 `json:"GridFeedIn_W"`
+}
+
+// GetLatestData fetches the latest inverter controller data. It requires
+// an API token.
+func (s *v2Source) GetLatestData(ctx context.Context) (*LatestData, error) {
+	var latestData LatestData
+	if err := s.get(ctx, "/api/v2/latestdata", &latestData); err != nil {
+		return nil, err
+	}
+	return &latestData, nil
+}
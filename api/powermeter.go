@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// PowerMeterData is the per-meter payload returned by the
+// /api/v2/powermeter endpoint, once for the production and once for the
+// consumption meter.
+type PowerMeterData struct {
+	VL1N        float64 `json:"v_l1_n"`
+	VL2N        float64 `json:"v_l2_n"`
+	VL3N        float64 `json:"v_l3_n"`
+	VL1L2       float64 `json:"v_l1_l2"`
+	VL2L3       float64 `json:"v_l2_l3"`
+	VL3L1       float64 `json:"v_l3_l1"`
+	WL1         float64 `json:"w_l1"`
+	WL2         float64 `json:"w_l2"`
+	WL3         float64 `json:"w_l3"`
+	KwhImported float64 `json:"kwh_imported"`
+	KwhExported float64 `json:"kwh_exported"`
+	Direction   string  `json:"direction"`
+}
+
+// GetPowerMeter fetches the production and consumption power meter
+// readings. It requires an API token.
+func (s *v2Source) GetPowerMeter(ctx context.Context) (production *PowerMeterData, consumption *PowerMeterData, err error) {
+	var meters []PowerMeterData
+	if err := s.get(ctx, "/api/v2/powermeter", &meters); err != nil {
+		return nil, nil, err
+	}
+
+	return splitPowerMeters(meters)
+}
+
+// splitPowerMeters splits the /api/v2/powermeter response array into its
+// production and consumption entries. It returns an error if either is
+// missing, which happens when the array is empty, has no entry with
+// Direction == "production", or has no entry with any other Direction.
+// Every entry whose Direction is not "production" counts as consumption.
+func splitPowerMeters(meters []PowerMeterData) (production *PowerMeterData, consumption *PowerMeterData, err error) {
+	for i := range meters {
+		m := meters[i]
+		switch m.Direction {
+		case "production":
+			production = &m
+		default:
+			consumption = &m
+		}
+	}
+
+	if production == nil || consumption == nil {
+		return nil, nil, fmt.Errorf("powermeter response from /api/v2/powermeter is missing a production or consumption meter")
+	}
+
+	return production, consumption, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import "testing"
+
+func TestSplitPowerMeters(t *testing.T) {
+	tests := []struct {
+		name    string
+		meters  []PowerMeterData
+		wantErr bool
+	}{
+		{
+			name: "production and consumption present",
+			meters: []PowerMeterData{
+				{Direction: "production", WL1: 100},
+				{Direction: "consumption", WL1: 200},
+			},
+		},
+		{
+			name: "order does not matter",
+			meters: []PowerMeterData{
+				{Direction: "consumption", WL1: 200},
+				{Direction: "production", WL1: 100},
+			},
+		},
+		{
+			name:    "empty response is rejected",
+			meters:  nil,
+			wantErr: true,
+		},
+		{
+			name: "missing production is rejected",
+			meters: []PowerMeterData{
+				{Direction: "consumption", WL1: 200},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing consumption is rejected",
+			meters: []PowerMeterData{
+				{Direction: "production", WL1: 100},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			production, consumption, err := splitPowerMeters(tt.meters)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if production == nil || production.Direction != "production" {
+				t.Errorf("production = %+v, want a production meter", production)
+			}
+			if consumption == nil || consumption.Direction != "consumption" {
+				t.Errorf("consumption = %+v, want a consumption meter", consumption)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Package api implements a client for the Sonnenbatterie HTTP API.
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// source abstracts the differences between the Sonnenbatterie firmware API
+// versions behind a single set of operations, so Sonnenbatterie's exported
+// methods stay version-agnostic for callers.
+type source interface {
+	HasToken() bool
+	GetStatus(ctx context.Context) (*Status, error)
+	GetPowerMeter(ctx context.Context) (production *PowerMeterData, consumption *PowerMeterData, err error)
+	GetLatestData(ctx context.Context) (*LatestData, error)
+	GetConfigurations(ctx context.Context) (*Configurations, error)
+	GetBatteryModules(ctx context.Context) ([]BatteryModule, error)
+}
+
+// Sonnenbatterie is a client for a single Sonnenbatterie storage battery.
+type Sonnenbatterie struct {
+	source source
+}
+
+// NewSonnenbatterie creates a new client for the Sonnenbatterie at the
+// given base URL. apiVersion selects the firmware API to talk to:
+//
+//   - "v2" (the default when empty): the token-authenticated REST API
+//     exposed by current firmware. token may be empty, in which case
+//     endpoints that require authentication are not available (see
+//     HasToken).
+//   - "v1": the unauthenticated /api/v1/status endpoint exposed by older
+//     Sonnen eco 6/8 units, which never got a v2 token endpoint.
+func NewSonnenbatterie(rawURL string, token string, apiVersion string) (*Sonnenbatterie, error) {
+	switch apiVersion {
+	case "", "v2":
+		s, err := newV2Source(rawURL, token)
+		if err != nil {
+			return nil, err
+		}
+		return &Sonnenbatterie{source: s}, nil
+	case "v1":
+		s, err := newV1Source(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		return &Sonnenbatterie{source: s}, nil
+	default:
+		return nil, fmt.Errorf("unknown api-version %q", apiVersion)
+	}
+}
+
+// HasToken reports whether this client was configured with an API token.
+func (s *Sonnenbatterie) HasToken() bool {
+	return s.source.HasToken()
+}
+
+// GetStatus fetches the current status of the battery.
+func (s *Sonnenbatterie) GetStatus(ctx context.Context) (*Status, error) {
+	return s.source.GetStatus(ctx)
+}
+
+// GetPowerMeter fetches the production and consumption power meter
+// readings. It requires an API token.
+func (s *Sonnenbatterie) GetPowerMeter(ctx context.Context) (production *PowerMeterData, consumption *PowerMeterData, err error) {
+	return s.source.GetPowerMeter(ctx)
+}
+
+// GetLatestData fetches the latest inverter controller data. It requires
+// an API token.
+func (s *Sonnenbatterie) GetLatestData(ctx context.Context) (*LatestData, error) {
+	return s.source.GetLatestData(ctx)
+}
+
+// GetConfigurations fetches the device's identity information. It requires
+// an API token.
+func (s *Sonnenbatterie) GetConfigurations(ctx context.Context) (*Configurations, error) {
+	return s.source.GetConfigurations(ctx)
+}
+
+// GetBatteryModules fetches the per-module telemetry of the battery pack.
+// It requires an API token.
+func (s *Sonnenbatterie) GetBatteryModules(ctx context.Context) ([]BatteryModule, error) {
+	return s.source.GetBatteryModules(ctx)
+}
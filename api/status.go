@@ -0,0 +1,23 @@
+package api
+
+import "context"
+
+// Status is the response of the /api/v2/status endpoint.
+type Status struct {
+	Uac                 float64 `json:"Uac"`
+	Fac                 float64 `json:"Fac"`
+	Rsoc                int     `json:"RSOC"`
+	Usoc                int     `json:"USOC"`
+	ConsumptionW        int     `json:"Consumption_W"`
+	ProductionW         int     `json:"Production_W"`
+	RemainingCapacityWh int     `json:"RemainingCapacity_Wh"`
+}
+
+// GetStatus fetches the current status of the battery.
+func (s *v2Source) GetStatus(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := s.get(ctx, "/api/v2/status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
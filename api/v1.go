@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// v1Source talks to the unauthenticated /api/v1/status endpoint exposed
+// by older Sonnen eco 6/8 firmware, which never got a v2 token endpoint.
+// It only has a single endpoint to draw from, so GetPowerMeter,
+// GetLatestData, GetConfigurations and GetBatteryModules are unsupported.
+type v1Source struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+func newV1Source(rawURL string) (*v1Source, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sonnenbatterie-url: %w", err)
+	}
+
+	return &v1Source{
+		baseURL: u,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// HasToken always reports false: the v1 API is unauthenticated.
+func (s *v1Source) HasToken() bool {
+	return false
+}
+
+// v1Status is the response of /api/v1/status.
+type v1Status struct {
+	Uac                 float64 `json:"Uac"`
+	Fac                 float64 `json:"Fac"`
+	Rsoc                int     `json:"RSOC"`
+	Usoc                int     `json:"USOC"`
+	ConsumptionW        int     `json:"Consumption_W"`
+	ProductionW         int     `json:"Production_W"`
+	RemainingCapacityWh int     `json:"RemainingCapacity_Wh"`
+}
+
+func (s *v1Source) GetStatus(ctx context.Context) (*Status, error) {
+	u := *s.baseURL
+	u.Path = "/api/v1/status"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, u.Path)
+	}
+
+	var v1 v1Status
+	if err := json.NewDecoder(resp.Body).Decode(&v1); err != nil {
+		return nil, err
+	}
+
+	return &Status{
+		Uac:                 v1.Uac,
+		Fac:                 v1.Fac,
+		Rsoc:                v1.Rsoc,
+		Usoc:                v1.Usoc,
+		ConsumptionW:        v1.ConsumptionW,
+		ProductionW:         v1.ProductionW,
+		RemainingCapacityWh: v1.RemainingCapacityWh,
+	}, nil
+}
+
+func (s *v1Source) GetPowerMeter(ctx context.Context) (production *PowerMeterData, consumption *PowerMeterData, err error) {
+	return nil, nil, fmt.Errorf("power meter data is not available via the v1 API")
+}
+
+func (s *v1Source) GetLatestData(ctx context.Context) (*LatestData, error) {
+	return nil, fmt.Errorf("latest data is not available via the v1 API")
+}
+
+func (s *v1Source) GetConfigurations(ctx context.Context) (*Configurations, error) {
+	return nil, fmt.Errorf("configurations are not available via the v1 API")
+}
+
+func (s *v1Source) GetBatteryModules(ctx context.Context) ([]BatteryModule, error) {
+	return nil, fmt.Errorf("battery module data is not available via the v1 API")
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// v2Source talks to the token-authenticated v2 REST API exposed by newer
+// Sonnenbatterie firmware.
+type v2Source struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+}
+
+func newV2Source(rawURL string, token string) (*v2Source, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sonnenbatterie-url: %w", err)
+	}
+
+	return &v2Source{
+		baseURL: u,
+		token:   token,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// HasToken reports whether this source was configured with an API token.
+func (s *v2Source) HasToken() bool {
+	return s.token != ""
+}
+
+func (s *v2Source) get(ctx context.Context, path string, out interface{}) error {
+	u := *s.baseURL
+	u.Path = path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if s.token != "" {
+		req.Header.Set("Auth-Token", s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, path)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
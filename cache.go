@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/simonswine/sonnenbatterie-exporter/api"
+)
+
+// cachedClient wraps an api.Sonnenbatterie client and caches its responses
+// for ttl, so that several Prometheus replicas scraping the same target
+// within one scrape interval don't hammer the battery's slow, rate-limited
+// HTTP API. A ttl of 0 disables caching.
+type cachedClient struct {
+	client *api.Sonnenbatterie
+	ttl    time.Duration
+
+	mu sync.Mutex
+
+	status                *api.Status
+	statusFetched         time.Time
+	production            *api.PowerMeterData
+	consumption           *api.PowerMeterData
+	powerMeterFetched     time.Time
+	latestData            *api.LatestData
+	latestDataFetched     time.Time
+	configurations        *api.Configurations
+	configurationsFetched time.Time
+	batteryModules        []api.BatteryModule
+	batteryModulesFetched time.Time
+}
+
+func newCachedClient(client *api.Sonnenbatterie, ttl time.Duration) *cachedClient {
+	return &cachedClient{client: client, ttl: ttl}
+}
+
+// cacheFresh reports whether a value fetched at fetchedAt is still within
+// ttl. A ttl of 0 always reports false, disabling caching.
+func cacheFresh(fetchedAt time.Time, ttl time.Duration) bool {
+	return time.Since(fetchedAt) < ttl
+}
+
+func (c *cachedClient) HasToken() bool {
+	return c.client.HasToken()
+}
+
+func (c *cachedClient) GetStatus(ctx context.Context) (status *api.Status, hit bool, fetchedAt time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status != nil && cacheFresh(c.statusFetched, c.ttl) {
+		return c.status, true, c.statusFetched, nil
+	}
+
+	status, err = c.client.GetStatus(ctx)
+	if err != nil {
+		return nil, false, time.Time{}, err
+	}
+	c.status = status
+	c.statusFetched = time.Now()
+	return status, false, c.statusFetched, nil
+}
+
+func (c *cachedClient) GetPowerMeter(ctx context.Context) (production *api.PowerMeterData, consumption *api.PowerMeterData, hit bool, fetchedAt time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.production != nil && c.consumption != nil && cacheFresh(c.powerMeterFetched, c.ttl) {
+		return c.production, c.consumption, true, c.powerMeterFetched, nil
+	}
+
+	production, consumption, err = c.client.GetPowerMeter(ctx)
+	if err != nil {
+		return nil, nil, false, time.Time{}, err
+	}
+	c.production, c.consumption = production, consumption
+	c.powerMeterFetched = time.Now()
+	return production, consumption, false, c.powerMeterFetched, nil
+}
+
+func (c *cachedClient) GetLatestData(ctx context.Context) (latestData *api.LatestData, hit bool, fetchedAt time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.latestData != nil && cacheFresh(c.latestDataFetched, c.ttl) {
+		return c.latestData, true, c.latestDataFetched, nil
+	}
+
+	latestData, err = c.client.GetLatestData(ctx)
+	if err != nil {
+		return nil, false, time.Time{}, err
+	}
+	c.latestData = latestData
+	c.latestDataFetched = time.Now()
+	return latestData, false, c.latestDataFetched, nil
+}
+
+func (c *cachedClient) GetConfigurations(ctx context.Context) (configurations *api.Configurations, hit bool, fetchedAt time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.configurations != nil && cacheFresh(c.configurationsFetched, c.ttl) {
+		return c.configurations, true, c.configurationsFetched, nil
+	}
+
+	configurations, err = c.client.GetConfigurations(ctx)
+	if err != nil {
+		return nil, false, time.Time{}, err
+	}
+	c.configurations = configurations
+	c.configurationsFetched = time.Now()
+	return configurations, false, c.configurationsFetched, nil
+}
+
+func (c *cachedClient) GetBatteryModules(ctx context.Context) (modules []api.BatteryModule, hit bool, fetchedAt time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.batteryModules != nil && cacheFresh(c.batteryModulesFetched, c.ttl) {
+		return c.batteryModules, true, c.batteryModulesFetched, nil
+	}
+
+	modules, err = c.client.GetBatteryModules(ctx)
+	if err != nil {
+		return nil, false, time.Time{}, err
+	}
+	c.batteryModules = modules
+	c.batteryModulesFetched = time.Now()
+	return modules, false, c.batteryModulesFetched, nil
+}
+
+// clientCache hands out one cachedClient per target, so the cache survives
+// across repeated /probe requests for the same target instead of being
+// rebuilt (and immediately useless) on every scrape.
+type clientCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*cachedClient
+}
+
+func newClientCache(ttl time.Duration) *clientCache {
+	return &clientCache{ttl: ttl, clients: make(map[string]*cachedClient)}
+}
+
+func (cc *clientCache) get(target string, cfg TargetConfig) (*cachedClient, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if c, ok := cc.clients[target]; ok {
+		return c, nil
+	}
+
+	a, err := api.NewSonnenbatterie(cfg.URL, cfg.Token, cfg.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCachedClient(a, cc.ttl)
+	cc.clients[target] = c
+	return c, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheFresh(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		fetchedAt time.Time
+		ttl       time.Duration
+		want      bool
+	}{
+		{"within ttl", now, time.Minute, true},
+		{"exactly at boundary", now.Add(-time.Minute), time.Minute, false},
+		{"past ttl", now.Add(-2 * time.Minute), time.Minute, false},
+		{"zero ttl always disables caching", now, 0, false},
+		{"never fetched", time.Time{}, time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheFresh(tt.fetchedAt, tt.ttl); got != tt.want {
+				t.Errorf("cacheFresh(%v, %v) = %v, want %v", tt.fetchedAt, tt.ttl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientCacheGetReusesPerTarget(t *testing.T) {
+	cc := newClientCache(5 * time.Second)
+	cfg := TargetConfig{URL: "http://battery.example"}
+
+	a, err := cc.get("target-a", cfg)
+	if err != nil {
+		t.Fatalf("get(target-a): %v", err)
+	}
+
+	again, err := cc.get("target-a", cfg)
+	if err != nil {
+		t.Fatalf("get(target-a) again: %v", err)
+	}
+	if a != again {
+		t.Error("get returned a different *cachedClient for the same target")
+	}
+
+	b, err := cc.get("target-b", cfg)
+	if err != nil {
+		t.Fatalf("get(target-b): %v", err)
+	}
+	if a == b {
+		t.Error("get returned the same *cachedClient for different targets")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the exporter's configuration file, listing the Sonnenbatterie
+// devices that can be scraped through the /probe endpoint.
+type Config struct {
+	Targets map[string]TargetConfig `yaml:"targets"`
+}
+
+// TargetConfig describes how to reach a single Sonnenbatterie device.
+type TargetConfig struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+	// APIVersion selects the firmware API to talk to, "v1" or "v2".
+	// Defaults to "v2" when empty. See api.NewSonnenbatterie.
+	APIVersion string `yaml:"api_version"`
+}
+
+func loadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	return &cfg, nil
+}
@@ -14,8 +14,6 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/hlog"
-
-	"github.com/simonswine/sonnenbatterie-exporter/api"
 )
 
 const timeout = 15 * time.Second
@@ -24,8 +22,54 @@ var log = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC
 	Timestamp().
 	Logger()
 
+var (
+	probeRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "solar_battery_probe_requests_total",
+			Help: "Total number of /probe requests handled by this exporter, by target and outcome.",
+		},
+		[]string{"target", "result"},
+	)
+	probeDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "solar_battery_probe_duration_seconds",
+			Help: "Duration of /probe requests handled by this exporter, by target.",
+		},
+		[]string{"target"},
+	)
+	apiRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "solar_battery_api_requests_total",
+			Help: "Total number of requests made to the Sonnenbatterie API by target, endpoint and status.",
+		},
+		[]string{"target", "endpoint", "status"},
+	)
+	scrapeDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "solar_battery_scrape_duration_seconds",
+			Help: "Duration of all Sonnenbatterie API calls made during a scrape, by target.",
+		},
+		[]string{"target"},
+	)
+	cacheHitsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "solar_battery_cache_hits_total",
+			Help: "Total number of Sonnenbatterie API responses served from cache by target and endpoint.",
+		},
+		[]string{"target", "endpoint"},
+	)
+	cacheLastUpdateTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "solar_battery_cache_last_update_timestamp_seconds",
+			Help: "Unix timestamp of the last cache refresh by target and endpoint.",
+		},
+		[]string{"target", "endpoint"},
+	)
+)
+
 type collector struct {
-	api *api.Sonnenbatterie
+	api    *cachedClient
+	target string
 
 	gridVoltage            *prometheus.Desc
 	gridFrequency          *prometheus.Desc
@@ -38,11 +82,22 @@ type collector struct {
 	lastFullyCharged       *prometheus.Desc
 	fullChargeCapacity     *prometheus.Desc
 	remaningChargeCapacity *prometheus.Desc
+
+	up   *prometheus.Desc
+	info *prometheus.Desc
+
+	inverterPower     *prometheus.Desc
+	gridFeedIn        *prometheus.Desc
+	gridPurchase      *prometheus.Desc
+	moduleTemperature *prometheus.Desc
+	moduleVoltage     *prometheus.Desc
+	moduleCycleCount  *prometheus.Desc
 }
 
-func newCollector(api *api.Sonnenbatterie) *collector {
+func newCollector(api *cachedClient, target string) *collector {
 	return &collector{
-		api: api,
+		api:    api,
+		target: target,
 		gridVoltage: prometheus.NewDesc(
 			"solar_battery_grid_voltage",
 			"Solar battery Grid (AC) voltage",
@@ -109,6 +164,54 @@ func newCollector(api *api.Sonnenbatterie) *collector {
 			nil,
 			nil,
 		),
+		up: prometheus.NewDesc(
+			"solar_battery_up",
+			"Whether the last scrape of the Sonnenbatterie API succeeded",
+			nil,
+			nil,
+		),
+		info: prometheus.NewDesc(
+			"solar_battery_info",
+			"Sonnenbatterie device identity, always 1",
+			[]string{"serial", "model", "firmware", "operating_mode", "battery_system"},
+			nil,
+		),
+		inverterPower: prometheus.NewDesc(
+			"solar_battery_inverter_power_watts",
+			"Solar battery inverter power in watts",
+			nil,
+			nil,
+		),
+		gridFeedIn: prometheus.NewDesc(
+			"solar_battery_grid_feed_in_watts",
+			"Power currently fed into the grid in watts",
+			nil,
+			nil,
+		),
+		gridPurchase: prometheus.NewDesc(
+			"solar_battery_grid_purchase_watts",
+			"Power currently purchased from the grid in watts",
+			nil,
+			nil,
+		),
+		moduleTemperature: prometheus.NewDesc(
+			"solar_battery_module_temperature_celsius",
+			"Solar battery module temperature in degrees celsius",
+			[]string{"module"},
+			nil,
+		),
+		moduleVoltage: prometheus.NewDesc(
+			"solar_battery_module_voltage",
+			"Solar battery module voltage",
+			[]string{"module"},
+			nil,
+		),
+		moduleCycleCount: prometheus.NewDesc(
+			"solar_battery_module_cycle_count",
+			"Solar battery module charge cycle count",
+			[]string{"module"},
+			nil,
+		),
 	}
 }
 
@@ -122,17 +225,40 @@ func (c *collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.consumptionEnergy
 	ch <- c.productionPower
 	ch <- c.productionEnergy
+	ch <- c.up
+	ch <- c.info
+	ch <- c.inverterPower
+	ch <- c.gridFeedIn
+	ch <- c.gridPurchase
+	ch <- c.moduleTemperature
+	ch <- c.moduleVoltage
+	ch <- c.moduleCycleCount
 }
 
-func (c *collector) collectStatus(ch chan<- prometheus.Metric) {
+// recordRequest updates the request and cache metrics for endpoint based on
+// the hit and fetchedAt values returned alongside a cached API call. A
+// cache hit didn't make an upstream request, so it counts toward
+// cache_hits_total instead of api_requests_total.
+func (c *collector) recordRequest(endpoint string, hit bool, fetchedAt time.Time) {
+	if hit {
+		cacheHitsTotal.WithLabelValues(c.target, endpoint).Inc()
+	} else {
+		apiRequestsTotal.WithLabelValues(c.target, endpoint, "success").Inc()
+	}
+	cacheLastUpdateTimestamp.WithLabelValues(c.target, endpoint).Set(float64(fetchedAt.Unix()))
+}
+
+func (c *collector) collectStatus(ch chan<- prometheus.Metric) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	status, err := c.api.GetStatus(ctx)
+	status, hit, fetchedAt, err := c.api.GetStatus(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get status")
-		return
+		apiRequestsTotal.WithLabelValues(c.target, "status", "error").Inc()
+		return false
 	}
+	c.recordRequest("status", hit, fetchedAt)
 
 	ch <- prometheus.MustNewConstMetric(c.gridVoltage, prometheus.GaugeValue, status.Uac, "")
 	ch <- prometheus.MustNewConstMetric(c.gridFrequency, prometheus.GaugeValue, status.Fac)
@@ -141,17 +267,20 @@ func (c *collector) collectStatus(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.consumptionPower, prometheus.GaugeValue, float64(status.ConsumptionW), "")
 	ch <- prometheus.MustNewConstMetric(c.productionPower, prometheus.GaugeValue, float64(status.ProductionW), "")
 	ch <- prometheus.MustNewConstMetric(c.remaningChargeCapacity, prometheus.GaugeValue, float64(status.RemainingCapacityWh))
+	return true
 }
 
-func (c *collector) collectPowerMeter(ch chan<- prometheus.Metric) {
+func (c *collector) collectPowerMeter(ch chan<- prometheus.Metric) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	production, consumption, err := c.api.GetPowerMeter(ctx)
+	production, consumption, hit, fetchedAt, err := c.api.GetPowerMeter(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get power meter")
-		return
+		apiRequestsTotal.WithLabelValues(c.target, "powermeter", "error").Inc()
+		return false
 	}
+	c.recordRequest("powermeter", hit, fetchedAt)
 
 	ch <- prometheus.MustNewConstMetric(c.gridVoltage, prometheus.GaugeValue, consumption.VL1N, "L1")
 	ch <- prometheus.MustNewConstMetric(c.gridVoltage, prometheus.GaugeValue, consumption.VL2N, "L2")
@@ -169,27 +298,142 @@ func (c *collector) collectPowerMeter(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.productionPower, prometheus.GaugeValue, production.WL2, "L2")
 	ch <- prometheus.MustNewConstMetric(c.productionPower, prometheus.GaugeValue, production.WL3, "L3")
 	ch <- prometheus.MustNewConstMetric(c.productionEnergy, prometheus.CounterValue, production.KwhImported)
+	return true
 }
 
-func (c *collector) collectLatestData(ch chan<- prometheus.Metric) {
+func (c *collector) collectLatestData(ch chan<- prometheus.Metric) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	latestData, err := c.api.GetLatestData(ctx)
+	latestData, hit, fetchedAt, err := c.api.GetLatestData(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get latest data")
-		return
+		apiRequestsTotal.WithLabelValues(c.target, "latestdata", "error").Inc()
+		return false
 	}
+	c.recordRequest("latestdata", hit, fetchedAt)
 
 	ch <- prometheus.MustNewConstMetric(c.lastFullyCharged, prometheus.GaugeValue, (float64(time.Now().UnixNano())/1e9)-float64(latestData.IcStatus.SecondsSinceFullCharge))
 	ch <- prometheus.MustNewConstMetric(c.fullChargeCapacity, prometheus.GaugeValue, float64(latestData.FullChargeCapacity))
+	ch <- prometheus.MustNewConstMetric(c.inverterPower, prometheus.GaugeValue, latestData.InverterPowerW)
+
+	gridFeedIn, gridPurchase := splitGridFeedIn(latestData.GridFeedInW)
+	ch <- prometheus.MustNewConstMetric(c.gridFeedIn, prometheus.GaugeValue, gridFeedIn)
+	ch <- prometheus.MustNewConstMetric(c.gridPurchase, prometheus.GaugeValue, gridPurchase)
+	return true
+}
+
+// splitGridFeedIn decomposes the signed GridFeedIn_W reading from
+// /api/v2/latestdata into its separate feed-in and purchase components:
+// positive values mean power is being fed into the grid, negative values
+// mean power is being purchased from it.
+func splitGridFeedIn(gridFeedInW float64) (gridFeedIn, gridPurchase float64) {
+	if gridFeedInW > 0 {
+		return gridFeedInW, 0
+	}
+	return 0, -gridFeedInW
+}
+
+func (c *collector) collectBatteryModules(ch chan<- prometheus.Metric) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	modules, hit, fetchedAt, err := c.api.GetBatteryModules(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get battery modules")
+		apiRequestsTotal.WithLabelValues(c.target, "battery", "error").Inc()
+		return false
+	}
+	c.recordRequest("battery", hit, fetchedAt)
+
+	for _, m := range modules {
+		ch <- prometheus.MustNewConstMetric(c.moduleTemperature, prometheus.GaugeValue, m.TemperatureCelsius, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.moduleVoltage, prometheus.GaugeValue, m.Voltage, m.Name)
+		ch <- prometheus.MustNewConstMetric(c.moduleCycleCount, prometheus.GaugeValue, float64(m.CycleCount), m.Name)
+	}
+	return true
+}
+
+func (c *collector) collectInfo(ch chan<- prometheus.Metric) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cfg, hit, fetchedAt, err := c.api.GetConfigurations(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get configurations")
+		apiRequestsTotal.WithLabelValues(c.target, "configurations", "error").Inc()
+		return false
+	}
+	c.recordRequest("configurations", hit, fetchedAt)
+
+	ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1,
+		cfg.Serial, cfg.Model, cfg.Firmware, cfg.OperatingMode, cfg.BatterySystem)
+	return true
 }
 
 func (c *collector) Collect(ch chan<- prometheus.Metric) {
-	c.collectStatus(ch)
+	start := time.Now()
+	ok := c.collectStatus(ch)
 	if c.api.HasToken() {
-		c.collectPowerMeter(ch)
-		c.collectLatestData(ch)
+		if !c.collectPowerMeter(ch) {
+			ok = false
+		}
+		if !c.collectLatestData(ch) {
+			ok = false
+		}
+		// Not every v2 firmware exposes /api/v2/configurations or
+		// /api/v2/battery, so their failure must not drag
+		// solar_battery_up to 0 for an otherwise healthy device; only
+		// the core status/powermeter/latestdata calls gate up.
+		c.collectInfo(ch)
+		c.collectBatteryModules(ch)
+	}
+	scrapeDurationSeconds.WithLabelValues(c.target).Observe(time.Since(start).Seconds())
+
+	upValue := 0.0
+	if ok {
+		upValue = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, upValue)
+}
+
+// probeHandler instantiates a fresh collector for the requested target and
+// serves its metrics, following the blackbox_exporter /probe pattern so a
+// single exporter instance can scrape a whole fleet of devices.
+func probeHandler(cfg *Config, clients *clientCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		targetName := r.URL.Query().Get("target")
+		if targetName == "" {
+			http.Error(w, "target parameter is missing", http.StatusBadRequest)
+			return
+		}
+
+		target, ok := cfg.Targets[targetName]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown target %q", targetName), http.StatusNotFound)
+			return
+		}
+
+		start := time.Now()
+
+		a, err := clients.get(targetName, target)
+		if err != nil {
+			probeRequestsTotal.WithLabelValues(targetName, "error").Inc()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		reg := prometheus.NewRegistry()
+		if err := reg.Register(newCollector(a, targetName)); err != nil {
+			probeRequestsTotal.WithLabelValues(targetName, "error").Inc()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(w, r)
+
+		probeDurationSeconds.WithLabelValues(targetName).Observe(time.Since(start).Seconds())
+		probeRequestsTotal.WithLabelValues(targetName, "success").Inc()
 	}
 }
 
@@ -198,33 +442,49 @@ func run() error {
 	var (
 		addr        string
 		metricsPath string
-		url         string
-		token       string
+		probePath   string
+		configFile  string
+		cacheTTL    time.Duration
 	)
 	flag.StringVar(&addr, "listen-address", ":9110", "The address to listen on for HTTP requests.")
-	flag.StringVar(&metricsPath, "metrics-path", "/metrics", "The path to mount the metrics endpoints.")
-	flag.StringVar(&url, "sonnenbatterie-url", "", "URL for the Sonnenbattery storage battery.")
-	flag.StringVar(&token, "sonnenbatterie-token", "", "Token for the Sonnenbattery storage battery API.")
+	flag.StringVar(&metricsPath, "metrics-path", "/metrics", "The path to mount the exporter's own metrics.")
+	flag.StringVar(&probePath, "probe-path", "/probe", "The path to mount the per-target probe endpoint.")
+	flag.StringVar(&configFile, "config-file", "sonnenbatterie.yml", "Path to the configuration file listing the Sonnenbatterie targets to scrape.")
+	flag.DurationVar(&cacheTTL, "cache-ttl", 5*time.Second, "How long to cache Sonnenbatterie API responses for, to survive Prometheus HA scrapes. Set to 0 to disable caching.")
 	flag.Parse()
 
-	if url == "" {
-		return fmt.Errorf("no sonnenbatterie-url set")
-	}
-	// Take token from environment if not set
-	if envToken := os.Getenv("SONNENBATTERIE_TOKEN"); token == "" && envToken != "" {
-		token = envToken
-	}
-
-	// create sonnenbatterie collector
-	a, err := api.NewSonnenbatterie(url, token)
+	cfg, err := loadConfig(configFile)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to load config file %q: %w", configFile, err)
+	}
+	if len(cfg.Targets) == 0 {
+		return fmt.Errorf("config file %q defines no targets", configFile)
 	}
 
-	coll := newCollector(a)
+	clients := newClientCache(cacheTTL)
 
+	// This registry, served on metricsPath, carries the exporter's own self-telemetry,
+	// including cumulative per-target counters/histograms that must
+	// survive across /probe requests to be useful for rate()-based
+	// alerting. The battery device metrics themselves are registered on
+	// a fresh registry for each /probe request instead.
 	reg := prometheus.NewRegistry()
-	if err := reg.Register(coll); err != nil {
+	if err := reg.Register(probeRequestsTotal); err != nil {
+		return err
+	}
+	if err := reg.Register(probeDurationSeconds); err != nil {
+		return err
+	}
+	if err := reg.Register(apiRequestsTotal); err != nil {
+		return err
+	}
+	if err := reg.Register(scrapeDurationSeconds); err != nil {
+		return err
+	}
+	if err := reg.Register(cacheHitsTotal); err != nil {
+		return err
+	}
+	if err := reg.Register(cacheLastUpdateTimestamp); err != nil {
 		return err
 	}
 
@@ -235,6 +495,9 @@ func run() error {
 	if err := reg.Register(collectors.NewGoCollector()); err != nil {
 		return err
 	}
+	if err := reg.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})); err != nil {
+		return err
+	}
 
 	// Install the logger handler with default output on the console
 	c := alice.New()
@@ -249,12 +512,14 @@ func run() error {
 			EnableOpenMetrics: true,
 		},
 	))
+	mux.HandleFunc(probePath, probeHandler(cfg, clients))
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(`<html>
 			<head><title>Sonnenbatterie Exporter</title></head>
 			<body>
 			<h1>Sonnenbatterie Exporter</h1>
 			<p><a href="` + metricsPath + `">Metrics</a></p>
+			<p><a href="` + probePath + `?target=">Probe</a> a configured target</p>
 			</body>
 			</html>`))
 	})
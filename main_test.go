@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestSplitGridFeedIn(t *testing.T) {
+	tests := []struct {
+		name             string
+		gridFeedInW      float64
+		wantGridFeedIn   float64
+		wantGridPurchase float64
+	}{
+		{"feeding in", 1500, 1500, 0},
+		{"purchasing", -800, 0, 800},
+		{"zero is purchase", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gridFeedIn, gridPurchase := splitGridFeedIn(tt.gridFeedInW)
+			if gridFeedIn != tt.wantGridFeedIn {
+				t.Errorf("gridFeedIn = %v, want %v", gridFeedIn, tt.wantGridFeedIn)
+			}
+			if gridPurchase != tt.wantGridPurchase {
+				t.Errorf("gridPurchase = %v, want %v", gridPurchase, tt.wantGridPurchase)
+			}
+		})
+	}
+}